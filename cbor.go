@@ -0,0 +1,81 @@
+package ecs
+
+import "io"
+
+// This file implements just enough of RFC 8949 (CBOR) encoding for
+// Snapshot.EncodeCBOR: unsigned ints, byte strings, text strings, arrays,
+// and maps. There is no decoder; a Snapshot is reconstructed via
+// Universe.Restore, not by parsing the wire format back.
+
+// CBOR major types, shifted into the high 3 bits of the initial byte.
+const (
+	cborMajorUint  = 0
+	cborMajorBytes = 2
+	cborMajorText  = 3
+	cborMajorArray = 4
+	cborMajorMap   = 5
+)
+
+func cborWriteHead(w io.Writer, major byte, n uint64) error {
+	var buf [9]byte
+	switch {
+	case n < 24:
+		buf[0] = major<<5 | byte(n)
+		_, err := w.Write(buf[:1])
+		return err
+	case n <= 0xff:
+		buf[0] = major<<5 | 24
+		buf[1] = byte(n)
+		_, err := w.Write(buf[:2])
+		return err
+	case n <= 0xffff:
+		buf[0] = major<<5 | 25
+		buf[1] = byte(n >> 8)
+		buf[2] = byte(n)
+		_, err := w.Write(buf[:3])
+		return err
+	case n <= 0xffffffff:
+		buf[0] = major<<5 | 26
+		buf[1] = byte(n >> 24)
+		buf[2] = byte(n >> 16)
+		buf[3] = byte(n >> 8)
+		buf[4] = byte(n)
+		_, err := w.Write(buf[:5])
+		return err
+	default:
+		buf[0] = major<<5 | 27
+		for i := 0; i < 8; i++ {
+			buf[8-i] = byte(n >> (8 * i))
+		}
+		_, err := w.Write(buf[:9])
+		return err
+	}
+}
+
+func cborWriteUint(w io.Writer, n uint64) error {
+	return cborWriteHead(w, cborMajorUint, n)
+}
+
+func cborWriteBytes(w io.Writer, b []byte) error {
+	if err := cborWriteHead(w, cborMajorBytes, uint64(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func cborWriteText(w io.Writer, s string) error {
+	if err := cborWriteHead(w, cborMajorText, uint64(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func cborWriteArrayHead(w io.Writer, n int) error {
+	return cborWriteHead(w, cborMajorArray, uint64(n))
+}
+
+func cborWriteMapHead(w io.Writer, n int) error {
+	return cborWriteHead(w, cborMajorMap, uint64(n))
+}
@@ -0,0 +1,34 @@
+package ecs
+
+// RangeAdded is like Range but only visits Props under key whose
+// AddedTick is at least sinceTick, letting a system process only the
+// Props added since its last run (typically the tick it captured then).
+func (u *Universe) RangeAdded(key string, sinceTick uint64, fn func(*Prop) bool) {
+	u.Range(key, func(p *Prop) bool {
+		if p.AddedTick() < sinceTick {
+			return true
+		}
+		return fn(p)
+	})
+}
+
+// RangeChanged is like Range but only visits Props under key whose
+// ChangedTick is at least sinceTick, letting a system process only the
+// Props changed since its last run.
+func (u *Universe) RangeChanged(key string, sinceTick uint64, fn func(*Prop) bool) {
+	u.Range(key, func(p *Prop) bool {
+		if p.ChangedTick() < sinceTick {
+			return true
+		}
+		return fn(p)
+	})
+}
+
+// RangeRemoved visits recently removed (Entity, key) pairs for key. Only
+// a small, fixed-size window of the most recent removals is retained per
+// key, so RangeRemoved does not see every removal ever made. Visiting
+// entries does not clear them: a later call visits the same entries
+// again until newer removals overwrite them.
+func (u *Universe) RangeRemoved(key string, fn func(e *Entity, key string) bool) {
+	u.propDB.rangeRemoved(key, fn)
+}
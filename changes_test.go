@@ -0,0 +1,72 @@
+package ecs
+
+import "testing"
+
+func TestRangeAddedAndChanged(t *testing.T) {
+	var u Universe
+
+	e1 := u.Entity()
+	e1.Put("health", 100)
+
+	sinceTick := u.Tick()
+
+	e2 := u.Entity()
+	e2.Put("health", 50)
+
+	gotAdded := 0
+	u.RangeAdded("health", sinceTick, func(*Prop) bool { gotAdded++; return true })
+	if want := 1; gotAdded != want {
+		t.Errorf("TestRangeAddedAndChanged(): got %d added, want %d", gotAdded, want)
+	}
+
+	e1.Get("health").PutData(90)
+
+	gotChanged := 0
+	u.RangeChanged("health", sinceTick, func(*Prop) bool { gotChanged++; return true })
+	if want := 2; gotChanged != want {
+		t.Errorf("TestRangeAddedAndChanged(): got %d changed, want %d", gotChanged, want)
+	}
+}
+
+func TestRangeChangedViaCompareAndSwapAndSwapData(t *testing.T) {
+	var u Universe
+
+	e1 := u.Entity()
+	e1.Put("mana", 100)
+	e2 := u.Entity()
+	e2.Put("mana", 100)
+
+	sinceTick := u.Tick()
+
+	e1.Get("mana").CompareAndSwapData(100, 90)
+	e2.Get("mana").SwapData(90)
+
+	gotChanged := 0
+	u.RangeChanged("mana", sinceTick, func(*Prop) bool { gotChanged++; return true })
+	if want := 2; gotChanged != want {
+		t.Errorf("TestRangeChangedViaCompareAndSwapAndSwapData(): got %d changed, want %d", gotChanged, want)
+	}
+}
+
+func TestRangeRemoved(t *testing.T) {
+	var u Universe
+
+	e := u.Entity()
+	e.Put("stunned")
+	e.Remove("stunned")
+
+	got := 0
+	u.RangeRemoved("stunned", func(gotEntity *Entity, gotKey string) bool {
+		got++
+		if gotEntity != e {
+			t.Errorf("TestRangeRemoved(): got Entity %v, want %v", gotEntity, e)
+		}
+		if gotKey != "stunned" {
+			t.Errorf("TestRangeRemoved(): got key %q, want %q", gotKey, "stunned")
+		}
+		return true
+	})
+	if want := 1; got != want {
+		t.Errorf("TestRangeRemoved(): got %d removed entries, want %d", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package ecs
+
+import "sync"
+
+// PropCodec encodes and decodes the Data carried by Props under a given
+// key so it can round-trip through a Snapshot or CommandLog.
+type PropCodec interface {
+	// EncodeProp encodes data, as returned by Prop.Data, to bytes.
+	EncodeProp(data any) ([]byte, error)
+	// DecodeProp decodes bytes previously returned by EncodeProp back
+	// into the value to pass to Prop.PutData.
+	DecodeProp(raw []byte) (any, error)
+}
+
+// propCodecs maps a Prop key to its registered PropCodec.
+var propCodecs sync.Map // string -> PropCodec
+
+// RegisterPropCodec registers codec as the way to encode and decode the
+// Data of Props under key for Snapshot, Restore, and CommandLog replay.
+// Keys with no registered codec are skipped by Snapshot, and Puts under
+// them are not recorded by a CommandLog (Removes still are).
+//
+// RegisterPropCodec is meant to be called during program initialization
+// and is not safe for concurrent use with Snapshot, Restore, or a
+// Universe's CommandLog.
+func RegisterPropCodec(key string, codec PropCodec) {
+	propCodecs.Store(key, codec)
+}
+
+func lookupPropCodec(key string) (PropCodec, bool) {
+	v, ok := propCodecs.Load(key)
+	if !ok {
+		return nil, false
+	}
+	return v.(PropCodec), true
+}
@@ -0,0 +1,131 @@
+package ecs
+
+import "sync"
+
+// LogOp identifies the kind of operation recorded by a LogEntry.
+type LogOp int
+
+const (
+	// LogPut records an Entity.Put call.
+	LogPut LogOp = iota
+	// LogRemove records an Entity.Remove call.
+	LogRemove
+	// LogDelete records an Entity.Delete call.
+	LogDelete
+)
+
+// LogEntry is a single recorded operation in a CommandLog.
+type LogEntry struct {
+	Seq      uint64
+	Op       LogOp
+	EntityID uint64
+	Key      string // unset for LogDelete
+	Data     []byte // codec-encoded Prop data, set only for LogPut
+}
+
+// CommandLog is an append-only, monotonically-sequenced log of the
+// structural operations (Put, Remove, Delete) applied to a Universe's
+// Entities, recorded via Universe.SetCommandLog. A CommandLog can be
+// replayed with Replay to reconstruct the Universe it recorded, enabling
+// save/load, deterministic testing, network sync, and time-travel
+// debugging.
+//
+// CommandLog is safe for concurrent use.
+type CommandLog struct {
+	mu      sync.Mutex
+	seq     uint64
+	entries []LogEntry
+}
+
+// Append records an entry with the next sequence number and returns that
+// sequence number.
+func (l *CommandLog) Append(op LogOp, entityID uint64, key string, data []byte) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	l.entries = append(l.entries, LogEntry{Seq: l.seq, Op: op, EntityID: entityID, Key: key, Data: data})
+	return l.seq
+}
+
+// Entries returns a snapshot of the LogEntry values recorded so far, in
+// sequence order.
+func (l *CommandLog) Entries() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]LogEntry, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+// Replay applies log's recorded operations, in sequence order, to a
+// fresh Universe and returns it. LogPut entries whose key has no
+// registered PropCodec (see RegisterPropCodec) are skipped.
+func Replay(log *CommandLog) (*Universe, error) {
+	u := &Universe{}
+	entities := map[uint64]*Entity{}
+	entityFor := func(id uint64) *Entity {
+		if e, ok := entities[id]; ok {
+			return e
+		}
+		e := u.Entity()
+		e.id = id
+		u.advanceNextIDPast(id)
+		entities[id] = e
+		return e
+	}
+	for _, entry := range log.Entries() {
+		e := entityFor(entry.EntityID)
+		switch entry.Op {
+		case LogPut:
+			codec, ok := lookupPropCodec(entry.Key)
+			if !ok {
+				continue
+			}
+			data, err := codec.DecodeProp(entry.Data)
+			if err != nil {
+				return nil, err
+			}
+			e.Put(entry.Key, data)
+		case LogRemove:
+			e.Remove(entry.Key)
+		case LogDelete:
+			e.Delete()
+		}
+	}
+	return u, nil
+}
+
+// logPut records a Put on entityID to the Universe's attached
+// CommandLog, if any, skipping Props under keys with no registered
+// PropCodec.
+func (u *Universe) logPut(entityID uint64, p *Prop) {
+	log := u.log.Load()
+	if log == nil {
+		return
+	}
+	codec, ok := lookupPropCodec(p.key)
+	if !ok {
+		return
+	}
+	data, err := codec.EncodeProp(p.Data())
+	if err != nil {
+		return
+	}
+	log.Append(LogPut, entityID, p.key, data)
+}
+
+// logRemove records a Remove on entityID to the Universe's attached
+// CommandLog, if any.
+func (u *Universe) logRemove(entityID uint64, key string) {
+	if log := u.log.Load(); log != nil {
+		log.Append(LogRemove, entityID, key, nil)
+	}
+}
+
+// logDelete records a Delete on entityID to the Universe's attached
+// CommandLog, if any.
+func (u *Universe) logDelete(entityID uint64) {
+	if log := u.log.Load(); log != nil {
+		log.Append(LogDelete, entityID, "", nil)
+	}
+}
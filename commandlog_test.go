@@ -0,0 +1,93 @@
+package ecs
+
+import "testing"
+
+func TestCommandLogReplay(t *testing.T) {
+	RegisterPropCodec("cmdlogtest-hp", intCodec{})
+
+	var u Universe
+	var log CommandLog
+	u.SetCommandLog(&log)
+
+	e := u.Entity()
+	e.Put("cmdlogtest-hp", 10)
+	e.Put("cmdlogtest-hp", 5)
+	e.Remove("cmdlogtest-hp")
+
+	e2 := u.Entity()
+	e2.Put("cmdlogtest-hp", 99)
+	e2.Delete()
+
+	if got, want := len(log.Entries()), 5; got != want {
+		t.Fatalf("TestCommandLogReplay(): got %d log entries, want %d", got, want)
+	}
+
+	replayed, err := Replay(&log)
+	if err != nil {
+		t.Fatalf("TestCommandLogReplay(): Replay() error = %v", err)
+	}
+
+	got := 0
+	replayed.Range("cmdlogtest-hp", func(*Prop) bool { got++; return true })
+	if want := 0; got != want {
+		t.Errorf("TestCommandLogReplay(): got %d live Props after replay, want %d", got, want)
+	}
+}
+
+func TestCommandLogReplayPreservesEntityID(t *testing.T) {
+	RegisterPropCodec("cmdlogtest-id", intCodec{})
+
+	var u Universe
+	var log CommandLog
+	u.SetCommandLog(&log)
+
+	u.Entity() // Never appears in the log.
+	b := u.Entity()
+	b.Put("cmdlogtest-id", 1)
+
+	replayed, err := Replay(&log)
+	if err != nil {
+		t.Fatalf("TestCommandLogReplayPreservesEntityID(): Replay() error = %v", err)
+	}
+
+	var gotID uint64
+	replayed.Range("cmdlogtest-id", func(p *Prop) bool {
+		gotID = p.Entity().ID()
+		return true
+	})
+	if gotID != b.ID() {
+		t.Errorf("TestCommandLogReplayPreservesEntityID(): got replayed ID %d, want %d", gotID, b.ID())
+	}
+}
+
+func TestReplayAdvancesNextID(t *testing.T) {
+	RegisterPropCodec("cmdlogtest-nextid", intCodec{})
+
+	var u Universe
+	var log CommandLog
+	u.SetCommandLog(&log)
+
+	u.Entity() // ID 1, never appears in the log.
+	e2 := u.Entity()
+	e2.Put("cmdlogtest-nextid", 1) // ID 2.
+
+	replayed, err := Replay(&log)
+	if err != nil {
+		t.Fatalf("TestReplayAdvancesNextID(): Replay() error = %v", err)
+	}
+
+	next := replayed.Entity()
+	if next.ID() <= e2.ID() {
+		t.Errorf("TestReplayAdvancesNextID(): got new Entity ID %d, want greater than replayed ID %d", next.ID(), e2.ID())
+	}
+}
+
+func TestCommandLogAppendSequence(t *testing.T) {
+	var log CommandLog
+	first := log.Append(LogPut, 1, "a", nil)
+	second := log.Append(LogRemove, 1, "a", nil)
+
+	if first != 1 || second != 2 {
+		t.Errorf("TestCommandLogAppendSequence(): got seqs %d, %d, want 1, 2", first, second)
+	}
+}
@@ -0,0 +1,59 @@
+package ecs
+
+import "sync"
+
+// Commands batches structural mutations to a Universe — Put, Remove,
+// Delete, and Spawn — so that code iterating with Range or RangeCommands
+// can schedule world edits without mutating the Universe, and its Props,
+// out from under the iteration.
+//
+// Queued commands are only applied when Flush is called, which runs them
+// in FIFO order relative to this Commands buffer. Flush is cheap when the
+// buffer is empty.
+//
+// Commands is safe for concurrent use.
+type Commands struct {
+	mu   sync.Mutex
+	cmds []func(*Universe)
+}
+
+// Put queues a Put(key, data...) call on e to run on the next Flush. See
+// Entity.Put for the exact semantics.
+func (c *Commands) Put(e *Entity, key string, data ...any) {
+	c.enqueue(func(*Universe) { e.Put(key, data...) })
+}
+
+// Remove queues a Remove(key) call on e to run on the next Flush.
+func (c *Commands) Remove(e *Entity, key string) {
+	c.enqueue(func(*Universe) { e.Remove(key) })
+}
+
+// Delete queues a Delete call on e to run on the next Flush.
+func (c *Commands) Delete(e *Entity) {
+	c.enqueue(func(*Universe) { e.Delete() })
+}
+
+// Spawn queues the creation of a new Entity in the Universe, calling fn
+// with it on the next Flush so the caller can Put its initial Props.
+func (c *Commands) Spawn(fn func(*Entity)) {
+	c.enqueue(func(u *Universe) { fn(u.Entity()) })
+}
+
+func (c *Commands) enqueue(cmd func(*Universe)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cmds = append(c.cmds, cmd)
+}
+
+// Flush applies all commands queued so far, in FIFO order, against u and
+// clears the queue. Commands queued by a command while Flush is running
+// are applied on the next Flush.
+func (c *Commands) Flush(u *Universe) {
+	c.mu.Lock()
+	cmds := c.cmds
+	c.cmds = nil
+	c.mu.Unlock()
+	for _, cmd := range cmds {
+		cmd(u)
+	}
+}
@@ -0,0 +1,55 @@
+package ecs
+
+import "testing"
+
+func TestCommandsFlushOrder(t *testing.T) {
+	var u Universe
+	e := u.Entity()
+
+	var cmds Commands
+	cmds.Put(e, "a")
+	cmds.Put(e, "b")
+	cmds.Remove(e, "a")
+
+	if e.Has("a") || e.Has("b") {
+		t.Fatalf("TestCommandsFlushOrder(): commands applied before Flush")
+	}
+
+	cmds.Flush(&u)
+
+	if e.Has("a") {
+		t.Errorf("TestCommandsFlushOrder(): got Has(%q) = true after Flush, want false", "a")
+	}
+	if !e.Has("b") {
+		t.Errorf("TestCommandsFlushOrder(): got Has(%q) = false after Flush, want true", "b")
+	}
+}
+
+func TestCommandsSpawn(t *testing.T) {
+	var u Universe
+
+	var cmds Commands
+	cmds.Spawn(func(e *Entity) { e.Put("spawned") })
+	cmds.Flush(&u)
+
+	got := 0
+	u.Range("spawned", func(*Prop) bool { got++; return true })
+	if want := 1; got != want {
+		t.Errorf("TestCommandsSpawn(): got %d spawned Entities, want %d", got, want)
+	}
+}
+
+func TestRangeCommandsFlushesAfterRange(t *testing.T) {
+	var u Universe
+	e := u.Entity()
+	e.Put("pending")
+
+	u.RangeCommands("pending", func(p *Prop, cmds *Commands) bool {
+		cmds.Remove(p.Entity(), "pending")
+		return true
+	})
+
+	if e.Has("pending") {
+		t.Errorf("TestRangeCommandsFlushesAfterRange(): expected queued Remove to be flushed")
+	}
+}
@@ -23,9 +23,23 @@ import (
 // Entity is safe for concurrent use.
 type Entity struct {
 	u        *Universe
+	id       uint64
 	props    sync.Map // string -> *Prop
 	deleted  bool
 	deleteMu sync.RWMutex
+
+	parent   *Entity
+	children []*Entity
+}
+
+// ID returns the Entity's ID, stable and unique within the Universe that
+// created it via Universe.Entity, or 0 for an Entity with no Universe.
+// ID is used to identify Entities in a Snapshot and CommandLog.
+func (e *Entity) ID() uint64 {
+	if e == nil {
+		return 0
+	}
+	return e.id
 }
 
 // Has returns true if the Entity contains the Prop.
@@ -42,6 +56,9 @@ func (e *Entity) Has(key string) bool { return e.Get(key) != nil }
 
 // Get returns the requested Prop or nil if none exists.
 func (e *Entity) Get(key string) *Prop {
+	if e == nil {
+		return nil
+	}
 	e.deleteMu.RLock()
 	defer e.deleteMu.RUnlock()
 	return e.loadProp(key)
@@ -76,7 +93,12 @@ func (e *Entity) Put(key string, data ...any) (prop *Prop) {
 	if e.deleted {
 		return nil
 	}
-	defer func() { prop.PutData(data...) }()
+	defer func() {
+		prop.PutData(data...)
+		if e.u != nil {
+			e.u.logPut(e.id, prop)
+		}
+	}()
 	if prop := e.loadProp(key); prop != nil {
 		return prop
 	}
@@ -110,13 +132,37 @@ func (e *Entity) Remove(key string) (removed *Prop) {
 	}
 	prop := x.(*Prop)
 	prop.detatch()
+	if e.u != nil {
+		e.u.trackRemoved(e, key)
+		e.u.logRemove(e.id, key)
+	}
 	return prop
 }
 
 func (e *Entity) removeKey(key string) { e.props.Delete(key) }
 
-// Delete deletes the Entity by calling Delete on all its Props
-// and removing it from the Universe.
+// rangeOwnProps visits every live Prop directly stored on e under a
+// single hold of e.deleteMu, freezing e against concurrent Delete for
+// the duration of the call. Used by Snapshot to serialize a consistent
+// view of one Entity's Props even though it discovers Entities by
+// ranging the propDB one key at a time.
+func (e *Entity) rangeOwnProps(fn func(key string, p *Prop)) {
+	e.deleteMu.RLock()
+	defer e.deleteMu.RUnlock()
+	if e.deleted {
+		return
+	}
+	e.props.Range(func(key, value any) bool {
+		if p := value.(*Prop); !p.Removed() {
+			fn(key.(string), p)
+		}
+		return true
+	})
+}
+
+// Delete deletes the Entity by calling Delete on all its Props,
+// removing it from the Universe, detaching it from its parent (see
+// SetParent), and cascading Delete to all of its Children.
 //
 // Example:
 //
@@ -132,13 +178,38 @@ func (e *Entity) Delete() {
 		return
 	}
 	e.deleteMu.Lock()
-	defer e.deleteMu.Unlock()
+	if e.deleted {
+		e.deleteMu.Unlock()
+		return
+	}
 	// After holding deleteMu's lock, the map cannot be modified.
 	// Therefore the following Range is guaranteed to detatch all Props.
 	// Also, setting deleted below guarantees no mutation after we release deleteMu.
 	e.deleted = true // Mark the Entity Deleted.
+	u := e.u
 	// Detatch all Props.
-	e.props.Range(func(_, value any) bool { value.(*Prop).detatch(); return true })
+	e.props.Range(func(key, value any) bool {
+		value.(*Prop).detatch()
+		if u != nil {
+			u.trackRemoved(e, key.(string))
+		}
+		return true
+	})
 	e.props = sync.Map{} // Map data may now be GCed.
 	e.u = nil            // Unlink the Universe.
+	parent := e.parent
+	children := e.children
+	e.parent = nil
+	e.children = nil
+	e.deleteMu.Unlock()
+
+	if u != nil {
+		u.logDelete(e.id)
+	}
+	if parent != nil {
+		parent.removeChild(e)
+	}
+	for _, child := range children {
+		child.Delete()
+	}
 }
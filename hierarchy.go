@@ -0,0 +1,134 @@
+package ecs
+
+import "slices"
+
+// SetParent sets e's parent, detaching e from any previous parent's
+// Children first. Passing nil detaches e from its parent entirely.
+// Setting e as its own parent is a no-op, as is any parent that would
+// make e one of its own descendants (a cycle).
+//
+// Deleting parent cascades to e (see Entity.Delete); deleting e detaches
+// it from parent.
+func (e *Entity) SetParent(parent *Entity) {
+	if e == nil || e == parent {
+		return
+	}
+	for p := parent; p != nil; p = p.Parent() {
+		if p == e {
+			return // Would make e a descendant of itself.
+		}
+	}
+	e.deleteMu.Lock()
+	if e.deleted || e.parent == parent {
+		e.deleteMu.Unlock()
+		return
+	}
+	oldParent := e.parent
+	e.deleteMu.Unlock()
+
+	if oldParent != nil {
+		oldParent.removeChild(e)
+	}
+	// addChild no-ops if parent has been Deleted; in that case e must not
+	// be wired to it either, so only commit e.parent on success.
+	added := parent == nil
+	if parent != nil {
+		added = parent.addChild(e)
+	}
+
+	e.deleteMu.Lock()
+	if e.deleted {
+		e.deleteMu.Unlock()
+		if added && parent != nil {
+			parent.removeChild(e)
+		}
+		return
+	}
+	if added {
+		e.parent = parent
+	} else {
+		e.parent = nil
+	}
+	e.deleteMu.Unlock()
+}
+
+// Parent returns e's parent Entity, or nil if it has none.
+func (e *Entity) Parent() *Entity {
+	if e == nil {
+		return nil
+	}
+	e.deleteMu.RLock()
+	defer e.deleteMu.RUnlock()
+	return e.parent
+}
+
+// Children returns a snapshot of e's child Entities.
+func (e *Entity) Children() []*Entity {
+	if e == nil {
+		return nil
+	}
+	e.deleteMu.RLock()
+	defer e.deleteMu.RUnlock()
+	return slices.Clone(e.children)
+}
+
+// addChild appends child to e's children and reports whether it did so.
+// It no-ops and returns false if e has been Deleted.
+func (e *Entity) addChild(child *Entity) bool {
+	e.deleteMu.Lock()
+	defer e.deleteMu.Unlock()
+	if e.deleted {
+		return false
+	}
+	e.children = append(e.children, child)
+	return true
+}
+
+func (e *Entity) removeChild(child *Entity) {
+	e.deleteMu.Lock()
+	defer e.deleteMu.Unlock()
+	e.children = slices.DeleteFunc(e.children, func(c *Entity) bool { return c == child })
+}
+
+// RangeDescendants visits all descendants of root in depth-first order,
+// stopping if fn returns false.
+func (u *Universe) RangeDescendants(root *Entity, fn func(*Entity) bool) {
+	if root == nil {
+		return
+	}
+	u.rangeDescendants(root, map[*Entity]bool{root: true}, fn)
+}
+
+// rangeDescendants is RangeDescendants' recursive step. visited guards
+// against a cycle in the parent/child graph (SetParent rejects the
+// cycles it can see, but this is a cheap backstop) turning a traversal
+// into an infinite recursion.
+func (u *Universe) rangeDescendants(root *Entity, visited map[*Entity]bool, fn func(*Entity) bool) bool {
+	for _, child := range root.Children() {
+		if visited[child] {
+			continue
+		}
+		visited[child] = true
+		if !fn(child) {
+			return false
+		}
+		if !u.rangeDescendants(child, visited, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// RangeAncestors visits e's ancestors, starting from its immediate
+// Parent up to the root, stopping if fn returns false. A visited set
+// guards against a cycle in the parent graph turning this into an
+// infinite loop.
+func (u *Universe) RangeAncestors(e *Entity, fn func(*Entity) bool) {
+	visited := map[*Entity]bool{}
+	for p := e.Parent(); p != nil && !visited[p]; p = p.Parent() {
+		visited[p] = true
+		if !fn(p) {
+			return
+		}
+	}
+}
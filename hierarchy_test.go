@@ -0,0 +1,121 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHierarchySetParentAndChildren(t *testing.T) {
+	var u Universe
+	root := u.Entity()
+	child := u.Entity()
+
+	child.SetParent(root)
+
+	if got, want := child.Parent(), root; got != want {
+		t.Errorf("TestHierarchySetParentAndChildren(): got Parent() = %v, want %v", got, want)
+	}
+	gotChildren := root.Children()
+	if len(gotChildren) != 1 || gotChildren[0] != child {
+		t.Errorf("TestHierarchySetParentAndChildren(): got Children() = %v, want [%v]", gotChildren, child)
+	}
+
+	// Reparenting detaches from the old parent.
+	other := u.Entity()
+	child.SetParent(other)
+	if got := root.Children(); len(got) != 0 {
+		t.Errorf("TestHierarchySetParentAndChildren(): got old parent Children() = %v, want empty", got)
+	}
+	if got, want := child.Parent(), other; got != want {
+		t.Errorf("TestHierarchySetParentAndChildren(): got Parent() = %v, want %v", got, want)
+	}
+}
+
+func TestSetParentOnDeletedParent(t *testing.T) {
+	var u Universe
+	parent := u.Entity()
+	child := u.Entity()
+
+	parent.Delete()
+	child.SetParent(parent)
+
+	if got := child.Parent(); got != nil {
+		t.Errorf("TestSetParentOnDeletedParent(): got Parent() = %v, want nil", got)
+	}
+	if got := parent.Children(); len(got) != 0 {
+		t.Errorf("TestSetParentOnDeletedParent(): got deleted parent Children() = %v, want empty", got)
+	}
+}
+
+func TestSetParentRejectsCycle(t *testing.T) {
+	var u Universe
+	a := u.Entity()
+	b := u.Entity()
+
+	a.SetParent(b)
+	b.SetParent(a) // Would make a a descendant of itself; must no-op.
+
+	if got := b.Parent(); got != nil {
+		t.Errorf("TestSetParentRejectsCycle(): got b.Parent() = %v, want nil", got)
+	}
+	if got := a.Children(); len(got) != 0 {
+		t.Errorf("TestSetParentRejectsCycle(): got a.Children() = %v, want empty", got)
+	}
+
+	// Traversal must terminate even if a cycle somehow existed.
+	done := make(chan struct{})
+	go func() {
+		u.RangeDescendants(a, func(*Entity) bool { return true })
+		u.RangeAncestors(b, func(*Entity) bool { return true })
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("TestSetParentRejectsCycle(): RangeDescendants/RangeAncestors did not terminate")
+	}
+}
+
+func TestHierarchyCascadeDelete(t *testing.T) {
+	var u Universe
+	root := u.Entity()
+	child := u.Entity()
+	grandchild := u.Entity()
+
+	child.SetParent(root)
+	grandchild.SetParent(child)
+
+	root.Delete()
+
+	if !child.deleted || !grandchild.deleted {
+		t.Errorf("TestHierarchyCascadeDelete(): expected Delete to cascade to descendants")
+	}
+}
+
+func TestRangeDescendantsAndAncestors(t *testing.T) {
+	var u Universe
+	root := u.Entity()
+	child := u.Entity()
+	grandchild := u.Entity()
+
+	child.SetParent(root)
+	grandchild.SetParent(child)
+
+	var gotDescendants []*Entity
+	u.RangeDescendants(root, func(e *Entity) bool {
+		gotDescendants = append(gotDescendants, e)
+		return true
+	})
+	if want := 2; len(gotDescendants) != want {
+		t.Fatalf("TestRangeDescendantsAndAncestors(): got %d descendants, want %d", len(gotDescendants), want)
+	}
+
+	var gotAncestors []*Entity
+	u.RangeAncestors(grandchild, func(e *Entity) bool {
+		gotAncestors = append(gotAncestors, e)
+		return true
+	})
+	if want := []*Entity{child, root}; len(gotAncestors) != len(want) || gotAncestors[0] != want[0] || gotAncestors[1] != want[1] {
+		t.Errorf("TestRangeDescendantsAndAncestors(): got ancestors %v, want %v", gotAncestors, want)
+	}
+}
@@ -19,10 +19,12 @@ import "sync/atomic"
 //
 // Prop is safe for concurrent use.
 type Prop struct {
-	e        atomic.Pointer[Entity]
-	key      string
-	attached atomic.Bool
-	data     any
+	e           atomic.Pointer[Entity]
+	key         string
+	attached    atomic.Bool
+	data        atomic.Pointer[any]
+	addedTick   atomic.Uint64
+	changedTick atomic.Uint64
 }
 
 // Data gets the data from the Prop if any.
@@ -30,19 +32,90 @@ func (p *Prop) Data() any {
 	if p == nil {
 		return nil
 	}
-	return p.data
+	if v := p.data.Load(); v != nil {
+		return *v
+	}
+	return nil
 }
 
 // PutData sets the data in the Prop.
 func (p *Prop) PutData(data ...any) {
+	var v any
 	switch len(data) {
 	case 0:
-		p.data = nil
+		v = nil
 	case 1:
-		p.data = data[0]
+		v = data[0]
 	default:
-		p.data = data
+		v = data
+	}
+	p.data.Store(&v)
+	p.stampChangedTick()
+}
+
+// stampChangedTick records the Universe's current tick as this Prop's
+// ChangedTick, called by every method that mutates Data.
+func (p *Prop) stampChangedTick() {
+	if e := p.e.Load(); e != nil && e.u != nil {
+		p.changedTick.Store(e.u.tick.Load())
+	}
+}
+
+// AddedTick returns the Universe tick (see Universe.Tick) at which this
+// Prop was first Put on its Entity, or 0 if it was never associated with
+// a Universe.
+func (p *Prop) AddedTick() uint64 {
+	if p == nil {
+		return 0
+	}
+	return p.addedTick.Load()
+}
+
+// ChangedTick returns the Universe tick (see Universe.Tick) as of the
+// Prop's most recent PutData, SwapData, or successful CompareAndSwapData
+// call, or 0 if it was never associated with a Universe.
+func (p *Prop) ChangedTick() uint64 {
+	if p == nil {
+		return 0
 	}
+	return p.changedTick.Load()
+}
+
+// CompareAndSwapData executes the compare-and-swap operation for the
+// Prop's data: if the current data equals old, it is replaced with new
+// and CompareAndSwapData returns true.
+//
+// CompareAndSwapData panics if old and the current data have the same
+// dynamic type and that type is not comparable (e.g. a slice, map, or
+// func). Unlike sync/atomic.Value.CompareAndSwap, it does not require
+// old and new to share a dynamic type with each other or with the
+// Prop's current data: a mismatched type on either simply compares
+// unequal to the current data and CompareAndSwapData returns false.
+func (p *Prop) CompareAndSwapData(old, new any) bool {
+	oldPtr := p.data.Load()
+	var cur any
+	if oldPtr != nil {
+		cur = *oldPtr
+	}
+	if cur != old {
+		return false
+	}
+	if !p.data.CompareAndSwap(oldPtr, &new) {
+		return false
+	}
+	p.stampChangedTick()
+	return true
+}
+
+// SwapData atomically stores new as the Prop's data and returns the data
+// previously stored, or nil if there was none.
+func (p *Prop) SwapData(new any) (old any) {
+	oldPtr := p.data.Swap(&new)
+	p.stampChangedTick()
+	if oldPtr != nil {
+		return *oldPtr
+	}
+	return nil
 }
 
 // Key returns the Key for this Prop.
@@ -74,10 +147,22 @@ func (p *Prop) Remove() {
 	}
 	if e := p.e.Load(); e != nil {
 		e.removeKey(p.key)
+		if e.u != nil {
+			e.u.trackRemoved(e, p.key)
+		}
 	}
 	p.detatch()
 }
 
 func (p *Prop) detatch() { p.attached.Store(false); p.e.Store(nil) }
 
-func (p *Prop) attach(e *Entity) { p.e.Store(e); p.attached.Store(true) }
+func (p *Prop) attach(e *Entity) {
+	p.e.Store(e)
+	p.attached.Store(true)
+	var tick uint64
+	if e != nil && e.u != nil {
+		tick = e.u.tick.Load()
+	}
+	p.addedTick.Store(tick)
+	p.changedTick.Store(tick)
+}
@@ -61,3 +61,49 @@ func TestZeroProp(t *testing.T) {
 	}
 	p.Remove() // Remove should not panic.
 }
+
+func TestPropCompareAndSwapData(t *testing.T) {
+	var p Prop
+	p.PutData(1)
+
+	if got := p.CompareAndSwapData(2, 3); got {
+		t.Errorf("TestPropCompareAndSwapData(): got CompareAndSwapData(2, 3) = %v, want false", got)
+	}
+	if got, want := p.Data(), 1; got != want {
+		t.Errorf("TestPropCompareAndSwapData(): got Data() = %v, want %v after failed CAS", got, want)
+	}
+
+	if got := p.CompareAndSwapData(1, 2); !got {
+		t.Errorf("TestPropCompareAndSwapData(): got CompareAndSwapData(1, 2) = %v, want true", got)
+	}
+	if got, want := p.Data(), 2; got != want {
+		t.Errorf("TestPropCompareAndSwapData(): got Data() = %v, want %v after successful CAS", got, want)
+	}
+}
+
+func TestPropCompareAndSwapDataMismatchedTypeReturnsFalse(t *testing.T) {
+	var p Prop
+	p.PutData(1)
+
+	// A type mismatch against the current data compares unequal and
+	// returns false; it must not panic the way a non-comparable old
+	// would.
+	if got := p.CompareAndSwapData("not an int", 2); got {
+		t.Errorf(`TestPropCompareAndSwapDataMismatchedTypeReturnsFalse(): got CompareAndSwapData("not an int", 2) = %v, want false`, got)
+	}
+	if got, want := p.Data(), 1; got != want {
+		t.Errorf("TestPropCompareAndSwapDataMismatchedTypeReturnsFalse(): got Data() = %v, want %v", got, want)
+	}
+}
+
+func TestPropSwapData(t *testing.T) {
+	var p Prop
+	p.PutData("first")
+
+	if got, want := p.SwapData("second"), "first"; got != want {
+		t.Errorf("TestPropSwapData(): got SwapData() = %v, want %v", got, want)
+	}
+	if got, want := p.Data(), "second"; got != want {
+		t.Errorf("TestPropSwapData(): got Data() = %v, want %v", got, want)
+	}
+}
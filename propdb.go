@@ -1,17 +1,37 @@
 package ecs
 
 import (
+	"hash/maphash"
 	"slices"
 	"sync"
 	"sync/atomic"
 )
 
-// bucketMissesBeforeCompact is the number of misses required to compact the bucket.
+// bucketMissesBeforeCompact is the number of misses required to compact
+// a key's chunkList.
 const bucketMissesBeforeCompact = 500
 
-// propDB maintains a database of all Props.
+// removedRingSize is the number of removed (Entity, key) pairs retained
+// per key for RangeRemoved. Older removals are overwritten.
+const removedRingSize = 64
+
+// propChunkSize is the number of *Prop slots per propChunk.
+const propChunkSize = 64
+
+// propTrieFanout is the number of child slots per propTrieNode. Each
+// trie level consumes one byte of a key's hash, giving 256-way fan-out.
+const propTrieFanout = 256
+
+// keySeed is fixed for the lifetime of the process so that repeated
+// lookups of the same key always walk the same trie path.
+var keySeed = maphash.MakeSeed()
+
+// propDB maintains a database of all Props, indexed by key in a
+// concurrent hash-trie so that Range and append on different keys never
+// contend with each other, and Range and append on the *same* key rarely
+// do either.
 type propDB struct {
-	data sync.Map // string -> *bucket
+	root propTrieNode
 }
 
 // Range over all Props in the Universe with a matching key and call fn.
@@ -21,65 +41,355 @@ type propDB struct {
 // Range does not block and fn may call any method on the Universe during iteration
 // including putting and removing Props, and even recursive Range calls.
 func (d *propDB) Range(key string, fn func(*Prop) bool) {
-	x, loaded := d.data.Load(key)
-	if !loaded {
+	entry := d.root.lookup(key, hashKey(key), 0)
+	if entry == nil {
 		return
 	}
-	b := x.(*bucket)
-	b.rangeProps(fn)
-	b.tryCompact()
+	entry.chunks.Load().rangeProps(fn)
+	entry.tryCompact()
 }
 
-func (d *propDB) getOrCreateBucket(key string) *bucket {
-	e, loaded := d.data.Load(key)
-	if !loaded {
-		e, _ = d.data.LoadOrStore(key, new(bucket))
+// bucketLen returns an approximate count of Props ever appended for
+// key, used by Query to pick the rarest key as its driver. It reads
+// chunkList.total directly rather than scanning, so it's O(1) rather
+// than O(n): it may overcount a key with many not-yet-compacted Removed
+// Props, but exactness doesn't matter for choosing a driver, only
+// roughly which key is rarest.
+func (d *propDB) bucketLen(key string) int {
+	entry := d.root.lookup(key, hashKey(key), 0)
+	if entry == nil {
+		return 0
 	}
-	return e.(*bucket)
+	return int(entry.chunks.Load().total.Load())
+}
+
+// keys returns a sorted snapshot of every prop key currently tracked by
+// the propDB, used by Snapshot to visit keys in a stable order.
+func (d *propDB) keys() []string {
+	var keys []string
+	d.root.collectKeys(&keys)
+	slices.Sort(keys)
+	return keys
 }
 
 func (d *propDB) append(key string, prop *Prop) {
-	b := d.getOrCreateBucket(key)
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	b.data = append(b.data, prop)
-}
-
-type bucket struct {
-	data   []*Prop
-	mu     sync.RWMutex
-	misses atomic.Int64
-}
-
-func (b *bucket) rangeProps(fn func(*Prop) bool) {
-	b.mu.RLock()
-	// Read the slice header inside the RLock.
-	// If the bucket is appended in the Range,
-	// we won't visit the new elements this time.
-	data := b.data
-	b.mu.RUnlock()
-	for _, e := range data {
-		if e.Removed() {
-			b.misses.Add(1)
-		} else if !fn(e) {
-			break
+	entry := d.root.loadOrCreateEntry(key, hashKey(key), 0)
+	entry.chunks.Load().append(prop)
+}
+
+// trackRemoved records that key was removed from e, for RangeRemoved.
+func (d *propDB) trackRemoved(e *Entity, key string) {
+	entry := d.root.loadOrCreateEntry(key, hashKey(key), 0)
+	entry.pushRemoved(e, key)
+}
+
+// rangeRemoved visits key's retained removed (Entity, key) pairs,
+// most-recent window only, stopping if fn returns false.
+func (d *propDB) rangeRemoved(key string, fn func(*Entity, string) bool) {
+	entry := d.root.lookup(key, hashKey(key), 0)
+	if entry == nil {
+		return
+	}
+	for _, r := range entry.snapshotRemoved() {
+		if !fn(r.entity, r.key) {
+			return
+		}
+	}
+}
+
+// rawLen returns the total number of Prop slots written for key across
+// all of its chunks, including Removed Props not yet compacted away.
+// Exposed package-internally for tests exercising compaction.
+func (d *propDB) rawLen(key string) int {
+	entry := d.root.lookup(key, hashKey(key), 0)
+	if entry == nil {
+		return 0
+	}
+	n := 0
+	for c := entry.chunks.Load().head.Load(); c != nil; c = c.next.Load() {
+		ln := int(c.len.Load())
+		if ln > propChunkSize {
+			ln = propChunkSize
+		}
+		for i := 0; i < ln; i++ {
+			if c.props[i].Load() != nil {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func hashKey(key string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(keySeed)
+	h.WriteString(key)
+	return h.Sum64()
+}
+
+// hashByte returns the byte of h consumed by trie level depth. depth
+// cycles through h's 8 bytes; a real 64-bit hash collision between two
+// different keys (astronomically unlikely) would make the trie grow
+// without bound at that path, same tradeoff any fixed-width hash index
+// accepts.
+func hashByte(h uint64, depth int) byte {
+	return byte(h >> (uint(depth%8) * 8))
+}
+
+// propTrieNode is one level of the concurrent hash-trie index from prop
+// key to its keyEntry. Each slot holds nil, a *trieSlot leaf, or a
+// *trieSlot pointing to a deeper propTrieNode, CAS-inserted so lookups
+// and inserts of different keys never contend with each other.
+type propTrieNode struct {
+	slots [propTrieFanout]atomic.Pointer[trieSlot]
+}
+
+// trieSlot occupies one propTrieNode slot: either a leaf keyEntry or a
+// deeper propTrieNode, distinguished by which field is non-nil.
+type trieSlot struct {
+	entry *keyEntry
+	node  *propTrieNode
+}
+
+// lookup returns the keyEntry for key, or nil if key has never been
+// appended to.
+func (n *propTrieNode) lookup(key string, h uint64, depth int) *keyEntry {
+	slot := n.slots[hashByte(h, depth)].Load()
+	switch {
+	case slot == nil:
+		return nil
+	case slot.node != nil:
+		return slot.node.lookup(key, h, depth+1)
+	case slot.entry.key == key:
+		return slot.entry
+	default:
+		return nil
+	}
+}
+
+// loadOrCreateEntry returns the keyEntry for key, creating the trie path
+// and a fresh keyEntry if this is the first append for key. Colliding
+// keys grow a deeper propTrieNode rather than overwriting one another.
+func (n *propTrieNode) loadOrCreateEntry(key string, h uint64, depth int) *keyEntry {
+	idx := hashByte(h, depth)
+	for {
+		slot := n.slots[idx].Load()
+		switch {
+		case slot == nil:
+			entry := newKeyEntry(key)
+			if n.slots[idx].CompareAndSwap(nil, &trieSlot{entry: entry}) {
+				return entry
+			}
+			// Lost the race to another inserter; retry against
+			// whatever is now in the slot.
+		case slot.node != nil:
+			return slot.node.loadOrCreateEntry(key, h, depth+1)
+		case slot.entry.key == key:
+			return slot.entry
+		default:
+			// Collision with a different key: grow a deeper node
+			// holding both entries.
+			child := &propTrieNode{}
+			collidingIdx := hashByte(hashKey(slot.entry.key), depth+1)
+			child.slots[collidingIdx].Store(&trieSlot{entry: slot.entry})
+			if n.slots[idx].CompareAndSwap(slot, &trieSlot{node: child}) {
+				return child.loadOrCreateEntry(key, h, depth+1)
+			}
+			// Lost the race; retry from this level.
+		}
+	}
+}
+
+func (n *propTrieNode) collectKeys(out *[]string) {
+	for i := range n.slots {
+		slot := n.slots[i].Load()
+		switch {
+		case slot == nil:
+		case slot.node != nil:
+			slot.node.collectKeys(out)
+		default:
+			*out = append(*out, slot.entry.key)
 		}
 	}
 }
 
-// tryCompact compacts the bucket if we have enough misses
-// by removing detatched Props.
-func (b *bucket) tryCompact() {
-	// Compact the bucket if we have enough misses.
-	if b.misses.Load() < bucketMissesBeforeCompact {
+// keyEntry is a hash-trie leaf: the prop key, its chunkList of Props,
+// and a small ring buffer of recently removed (Entity, key) pairs.
+type keyEntry struct {
+	key    string
+	chunks atomic.Pointer[chunkList]
+
+	removedMu  sync.Mutex
+	removed    []removedEntry
+	removedPos int
+}
+
+func newKeyEntry(key string) *keyEntry {
+	e := &keyEntry{key: key}
+	e.chunks.Store(newChunkList())
+	return e
+}
+
+// tryCompact swaps in a fresh chunkList containing only this key's live
+// Props once enough Removed Props have accumulated, so Range stops
+// paying to skip over them. The swap is a single CAS: if Props were
+// appended to the list while it was being scanned, the swap is skipped
+// for this round rather than risk losing them, and the next round of
+// misses will try again.
+//
+// A plain before/after check on total is not enough: append bumps the
+// chunk's len (which rangeProps' view is built from) before it stores
+// the Prop and bumps total, so a goroutine preempted in that window
+// would have its Prop silently missed by the scan yet still write it
+// into cur after compaction has swapped cur out from under it, losing
+// it for good. Checking reserved == total (see chunkList) both before
+// and after the scan rules this out: it's only true when every append
+// that has claimed a slot has also finished storing into it.
+func (e *keyEntry) tryCompact() {
+	for {
+		cur := e.chunks.Load()
+		if cur.misses.Load() < bucketMissesBeforeCompact {
+			return
+		}
+		reservedBefore, totalBefore := cur.reserved.Load(), cur.total.Load()
+		if reservedBefore != totalBefore {
+			return // An append is mid-flight; try again next round.
+		}
+		compacted := newChunkList()
+		cur.rangeProps(func(p *Prop) bool { compacted.append(p); return true })
+		if cur.reserved.Load() != reservedBefore || cur.total.Load() != totalBefore {
+			return // Appended to concurrently; try again next round.
+		}
+		if e.chunks.CompareAndSwap(cur, compacted) {
+			return
+		}
+		// Someone else already compacted (or replaced) this entry's
+		// chunkList; retry against whatever is current.
+	}
+}
+
+// removedEntry is one entry of a keyEntry's removal ring buffer.
+type removedEntry struct {
+	entity *Entity
+	key    string
+}
+
+func (e *keyEntry) pushRemoved(entity *Entity, key string) {
+	e.removedMu.Lock()
+	defer e.removedMu.Unlock()
+	entry := removedEntry{entity: entity, key: key}
+	if len(e.removed) < removedRingSize {
+		e.removed = append(e.removed, entry)
 		return
 	}
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	begin := 0 // Scan to the first unremoved Prop.
-	for ; begin < len(b.data) && b.data[begin].Removed(); begin++ {
+	e.removed[e.removedPos] = entry
+	e.removedPos = (e.removedPos + 1) % removedRingSize
+}
+
+func (e *keyEntry) snapshotRemoved() []removedEntry {
+	e.removedMu.Lock()
+	defer e.removedMu.Unlock()
+	return slices.Clone(e.removed)
+}
+
+// propChunk is a fixed-size, append-only array of Props. Slots are
+// claimed lock-free via a CAS-free atomic increment of len; once full, a
+// chunkList links a new propChunk onto next.
+type propChunk struct {
+	props [propChunkSize]atomic.Pointer[Prop]
+	len   atomic.Int32
+	next  atomic.Pointer[propChunk]
+}
+
+// chunkList is the lock-free, append-only list of propChunks backing a
+// single prop key.
+//
+// reserved and total track append's two commit phases separately:
+// reserved counts every append that has claimed a slot, total counts
+// only those that have finished storing into it. reserved == total
+// means no append is currently mid-flight, which tryCompact relies on
+// before it may safely swap the list out from under readers.
+type chunkList struct {
+	head     atomic.Pointer[propChunk]
+	tail     atomic.Pointer[propChunk]
+	misses   atomic.Int64
+	reserved atomic.Int64
+	total    atomic.Int64
+}
+
+func newChunkList() *chunkList {
+	c := &chunkList{}
+	first := &propChunk{}
+	c.head.Store(first)
+	c.tail.Store(first)
+	return c
+}
+
+// append adds p to the chunkList. It claims a slot in the tail chunk by
+// atomically incrementing its len; once a chunk is full it CAS-links a
+// new chunk and retries against the new tail. Different appenders to the
+// same key only contend on the tail chunk, never on the whole list.
+//
+// reserved is bumped before the slot is claimed and total only once p
+// has actually been stored into it, so a goroutine preempted between
+// claiming its slot and storing into it leaves reserved > total for the
+// whole chunkList, which tryCompact checks for before swapping the list.
+func (c *chunkList) append(p *Prop) {
+	c.reserved.Add(1)
+	for {
+		tail := c.tail.Load()
+		idx := tail.len.Add(1) - 1
+		if idx < propChunkSize {
+			tail.props[idx].Store(p)
+			c.total.Add(1)
+			return
+		}
+		next := tail.next.Load()
+		if next == nil {
+			if tail.next.CompareAndSwap(nil, &propChunk{}) {
+				next = tail.next.Load()
+			} else {
+				next = tail.next.Load()
+			}
+		}
+		c.tail.CompareAndSwap(tail, next)
+	}
+}
+
+// rangeProps visits every live Prop in the chunkList in append order,
+// stopping if fn returns false. It does this in two passes: the first
+// records how many slots of each existing chunk to visit, and the
+// second visits exactly those slots, so Props appended during the call
+// (even into a not-yet-full chunk) are not visited this pass, matching
+// the prior bucket-based implementation's behavior.
+func (c *chunkList) rangeProps(fn func(*Prop) bool) {
+	type view struct {
+		chunk *propChunk
+		n     int
+	}
+	stopAt := c.tail.Load()
+	var views []view
+	for chunk := c.head.Load(); ; chunk = chunk.next.Load() {
+		n := int(chunk.len.Load())
+		if n > propChunkSize {
+			n = propChunkSize
+		}
+		views = append(views, view{chunk, n})
+		if chunk == stopAt {
+			break
+		}
+	}
+	for _, v := range views {
+		for i := 0; i < v.n; i++ {
+			p := v.chunk.props[i].Load()
+			if p == nil {
+				continue
+			}
+			if p.Removed() {
+				c.misses.Add(1)
+			} else if !fn(p) {
+				return
+			}
+		}
 	}
-	// Remove the Props by compacting the bucket.
-	b.data = slices.CompactFunc(b.data[begin:], func(a, b *Prop) bool { return b.Removed() })
-	b.misses.Store(0)
 }
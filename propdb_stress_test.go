@@ -0,0 +1,83 @@
+package ecs
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestPropDBConcurrentAppendAndRange stresses concurrent append (via
+// Entity.Put) and Range on the same hot key, which used to serialize on
+// a single bucket lock.
+func TestPropDBConcurrentAppendAndRange(t *testing.T) {
+	const (
+		numWriters = 8
+		numPerGo   = 500
+	)
+
+	var u Universe
+	var wg sync.WaitGroup
+
+	for i := 0; i < numWriters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numPerGo; j++ {
+				u.Entity().Put("position", j)
+			}
+		}()
+	}
+
+	// Range concurrently with the writers; it should not race or panic.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			u.Range("position", func(p *Prop) bool { return true })
+		}
+	}()
+
+	wg.Wait()
+
+	got := 0
+	u.Range("position", func(*Prop) bool { got++; return true })
+	if want := numWriters * numPerGo; got != want {
+		t.Errorf("TestPropDBConcurrentAppendAndRange(): got %d live Props, want %d", got, want)
+	}
+}
+
+// TestPropDBCompactUnderConcurrentAppend stresses compaction racing with
+// appends to the same key: appends that happen concurrently with a
+// compaction pass must never be lost.
+func TestPropDBCompactUnderConcurrentAppend(t *testing.T) {
+	const testKey = "stress-compact"
+
+	var u Universe
+
+	// Build up enough Removed Props to trigger repeated compaction.
+	for i := 0; i < bucketMissesBeforeCompact*2; i++ {
+		e := u.Entity()
+		e.Put(testKey)
+		e.Remove(testKey)
+	}
+
+	var wg sync.WaitGroup
+	const numWriters = 4
+	const numPerGo = 200
+	for i := 0; i < numWriters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numPerGo; j++ {
+				u.Entity().Put(testKey)
+				u.Range(testKey, func(*Prop) bool { return true }) // Drives compaction.
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := 0
+	u.Range(testKey, func(*Prop) bool { got++; return true })
+	if want := numWriters * numPerGo; got != want {
+		t.Errorf("TestPropDBCompactUnderConcurrentAppend(): got %d live Props, want %d", got, want)
+	}
+}
@@ -14,16 +14,10 @@ func TestPropDBCompact(t *testing.T) {
 	e2 := u.Entity()
 	e2.Put(testKey)
 
-	// Fetch the propDB bucket.
-	x, ok := u.propDB.data.Load(testKey)
-	if !ok {
-		t.Fatal("TestPropDBCompact(): expected propDB bucket is missing")
-	}
-	b := x.(*bucket)
-
-	// Assert the bucket has length 2.
-	if gotLen := len(b.data); gotLen != 2 {
-		t.Fatalf("TestPropDBCompact(): expected bucket length 1, got %d", gotLen)
+	// Assert the key's chunkList has length 2 (the Removed Prop hasn't
+	// been compacted away yet).
+	if gotLen := u.propDB.rawLen(testKey); gotLen != 2 {
+		t.Fatalf("TestPropDBCompact(): expected raw length 2, got %d", gotLen)
 	}
 
 	// Do bucketMissesBeforeCompact misses for testKey.
@@ -31,9 +25,61 @@ func TestPropDBCompact(t *testing.T) {
 		u.Range(testKey, func(p *Prop) bool { return true })
 	}
 
-	// Assert the bucket is now compacted (is 1).
-	if gotLen := len(b.data); gotLen != 1 {
-		t.Errorf("TestPropDBCompact(): expected compacted bucket (length 1), got %d", gotLen)
+	// Assert the key's chunkList is now compacted (is 1).
+	if gotLen := u.propDB.rawLen(testKey); gotLen != 1 {
+		t.Errorf("TestPropDBCompact(): expected compacted raw length 1, got %d", gotLen)
+	}
+}
+
+func TestBucketLenIsO1(t *testing.T) {
+	const (
+		rareKey   = "bucketlen-rare"
+		commonKey = "bucketlen-common"
+	)
+
+	var u Universe
+	u.Entity().Put(rareKey)
+	for i := 0; i < 1000; i++ {
+		u.Entity().Put(commonKey)
+	}
+
+	if got := u.propDB.bucketLen(rareKey); got != 1 {
+		t.Errorf("TestBucketLenIsO1(): got bucketLen(rareKey) = %d, want 1", got)
+	}
+	if got := u.propDB.bucketLen(commonKey); got != 1000 {
+		t.Errorf("TestBucketLenIsO1(): got bucketLen(commonKey) = %d, want 1000", got)
+	}
+
+	// Query over keys no Entity has together matches nothing, with the
+	// common key listed first.
+	got := 0
+	u.Query([]string{commonKey, rareKey}, func(*Entity) bool { got++; return true })
+	if want := 0; got != want {
+		t.Errorf("TestBucketLenIsO1(): Query with disjoint keys got %d matches, want %d", got, want)
+	}
+}
+
+// TestPropDBCompactAbortsOnPendingAppend simulates an appender that has
+// claimed a slot (reserved bumped, chunk.len bumped) but has not yet
+// stored its Prop or bumped total, reproducing the race window
+// tryCompact must not compact across.
+func TestPropDBCompactAbortsOnPendingAppend(t *testing.T) {
+	const testKey = "pending-append"
+
+	var u Universe
+	e := u.propDB.root.loadOrCreateEntry(testKey, hashKey(testKey), 0)
+
+	cur := e.chunks.Load()
+	tail := cur.tail.Load()
+	tail.len.Add(1) // Claim a slot...
+	cur.reserved.Add(1)
+	// ...but never Store into it or bump total, as if preempted there.
+
+	cur.misses.Store(bucketMissesBeforeCompact)
+	e.tryCompact()
+
+	if got := e.chunks.Load(); got != cur {
+		t.Errorf("TestPropDBCompactAbortsOnPendingAppend(): tryCompact swapped the chunkList despite a pending append")
 	}
 }
 
@@ -0,0 +1,41 @@
+package ecs
+
+// Query visits Entities that have all of the given Prop keys, stopping
+// if fn returns false. Passing no keys visits nothing.
+//
+// Internally Query picks the requested key with the fewest Props as its
+// driver (counted approximately, including Removed Props not yet
+// compacted away) and probes each candidate Entity for the remaining keys, so
+// the work is bounded by the rarest key rather than the total number of
+// Entities.
+//
+// Example:
+//
+//	u.Query([]string{"position", "velocity"}, func(e *Entity) bool {
+//	    // e has both "position" and "velocity".
+//	    return true
+//	})
+func (u *Universe) Query(keys []string, fn func(*Entity) bool) {
+	if len(keys) == 0 {
+		return
+	}
+	driver := keys[0]
+	driverLen := u.bucketLen(driver)
+	for _, key := range keys[1:] {
+		if n := u.bucketLen(key); n < driverLen {
+			driver, driverLen = key, n
+		}
+	}
+	u.Range(driver, func(p *Prop) bool {
+		e := p.Entity()
+		if e == nil {
+			return true
+		}
+		for _, key := range keys {
+			if key != driver && !e.Has(key) {
+				return true
+			}
+		}
+		return fn(e)
+	})
+}
@@ -0,0 +1,55 @@
+package ecs
+
+import "testing"
+
+func TestQueryIntersection(t *testing.T) {
+	var u Universe
+
+	moving := u.Entity()
+	moving.Put("position")
+	moving.Put("velocity")
+
+	still := u.Entity()
+	still.Put("position")
+
+	got := 0
+	u.Query([]string{"position", "velocity"}, func(e *Entity) bool {
+		got++
+		if !e.Has("position") || !e.Has("velocity") {
+			t.Errorf("TestQueryIntersection(): visited Entity missing a queried key")
+		}
+		return true
+	})
+	if want := 1; got != want {
+		t.Errorf("TestQueryIntersection(): got %d matches, want %d", got, want)
+	}
+}
+
+func TestQueryNoKeys(t *testing.T) {
+	var u Universe
+	u.Entity().Put("position")
+
+	got := 0
+	u.Query(nil, func(*Entity) bool { got++; return true })
+	if want := 0; got != want {
+		t.Errorf("TestQueryNoKeys(): got %d matches, want %d", got, want)
+	}
+}
+
+func TestQueryStopsEarly(t *testing.T) {
+	var u Universe
+	for i := 0; i < 3; i++ {
+		e := u.Entity()
+		e.Put("position")
+		e.Put("velocity")
+	}
+
+	got := 0
+	u.Query([]string{"position", "velocity"}, func(e *Entity) bool {
+		got++
+		return false
+	})
+	if want := 1; got != want {
+		t.Errorf("TestQueryStopsEarly(): got %d matches, want %d", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package ecs
+
+import (
+	"context"
+	"sync"
+)
+
+// System is a unit of per-frame logic that operates on a Universe.
+//
+// Systems are grouped into stages by a Schedule and dispatched by
+// Universe.Run or Universe.RunParallel.
+type System func(*Universe)
+
+// Schedule is an ordered list of stages of Systems.
+//
+// Systems within a single stage have no ordering guarantees between them
+// and may be run concurrently by Universe.RunParallel. Stages themselves
+// always run in the order they were added.
+//
+// Schedule is not safe for concurrent use while stages are being added.
+type Schedule struct {
+	stages [][]System
+}
+
+// AddStage appends a new stage containing systems to the Schedule.
+func (s *Schedule) AddStage(systems ...System) {
+	s.stages = append(s.stages, systems)
+}
+
+// Run executes the Schedule's stages in order, running each stage's
+// Systems sequentially and flushing the Universe's Commands at the end
+// of each stage. Run returns early if ctx is done before a stage starts.
+func (u *Universe) Run(ctx context.Context, s *Schedule) {
+	for _, stage := range s.stages {
+		if ctx.Err() != nil {
+			return
+		}
+		for _, sys := range stage {
+			sys(u)
+		}
+		u.Commands().Flush(u)
+	}
+}
+
+// RunParallel executes the Schedule's stages in order, running all of a
+// stage's Systems concurrently and waiting for them to finish, then
+// flushing the Universe's Commands, before advancing to the next stage.
+// RunParallel returns early if ctx is done before a stage starts.
+func (u *Universe) RunParallel(ctx context.Context, s *Schedule) {
+	for _, stage := range s.stages {
+		if ctx.Err() != nil {
+			return
+		}
+		var wg sync.WaitGroup
+		for _, sys := range stage {
+			wg.Add(1)
+			go func(sys System) {
+				defer wg.Done()
+				sys(u)
+			}(sys)
+		}
+		wg.Wait()
+		u.Commands().Flush(u)
+	}
+}
@@ -0,0 +1,62 @@
+package ecs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestScheduleRun(t *testing.T) {
+	var u Universe
+	var order []int
+	var s Schedule
+	s.AddStage(func(*Universe) { order = append(order, 1) })
+	s.AddStage(func(*Universe) { order = append(order, 2) })
+
+	u.Run(context.Background(), &s)
+
+	if got, want := len(order), 2; got != want {
+		t.Fatalf("TestScheduleRun(): got %d stages run, want %d", got, want)
+	}
+	if order[0] != 1 || order[1] != 2 {
+		t.Errorf("TestScheduleRun(): got stage order %v, want [1 2]", order)
+	}
+}
+
+func TestScheduleRunParallel(t *testing.T) {
+	var u Universe
+	var stage1, stage2 atomic.Int64
+	var s Schedule
+	s.AddStage(
+		func(*Universe) { stage1.Add(1) },
+		func(*Universe) { stage1.Add(1) },
+	)
+	s.AddStage(func(*Universe) {
+		// By the time stage2 runs, stage1 must be fully applied.
+		if got, want := stage1.Load(), int64(2); got != want {
+			t.Errorf("TestScheduleRunParallel(): got stage1 = %d, want %d", got, want)
+		}
+		stage2.Add(1)
+	})
+
+	u.RunParallel(context.Background(), &s)
+
+	if got, want := stage2.Load(), int64(1); got != want {
+		t.Errorf("TestScheduleRunParallel(): got stage2 = %d, want %d", got, want)
+	}
+}
+
+func TestScheduleRunCanceled(t *testing.T) {
+	var u Universe
+	ran := false
+	var s Schedule
+	s.AddStage(func(*Universe) { ran = true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	u.Run(ctx, &s)
+
+	if ran {
+		t.Errorf("TestScheduleRunCanceled(): stage ran after ctx was canceled")
+	}
+}
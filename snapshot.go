@@ -0,0 +1,168 @@
+package ecs
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+)
+
+// PropSnapshot is the codec-encoded Data captured for a single Prop in a
+// Snapshot.
+type PropSnapshot struct {
+	Key  string
+	Data []byte
+}
+
+// EntitySnapshot is the ID and Prop set captured for a single Entity in
+// a Snapshot.
+type EntitySnapshot struct {
+	ID    uint64
+	Props []PropSnapshot
+}
+
+// Snapshot is a point-in-time capture of a Universe's Entities and their
+// Props, suitable for serialization via EncodeJSON / EncodeCBOR and
+// reconstruction via Universe.Restore.
+type Snapshot struct {
+	Entities []EntitySnapshot
+}
+
+// Snapshot captures every Entity and Prop in u whose key has a
+// registered PropCodec (see RegisterPropCodec); Props under keys with no
+// codec are skipped.
+//
+// Snapshot discovers Entities by iterating propDB keys in a stable
+// sorted order, but serializes each Entity's Props under a single hold
+// of its own deleteMu (see Entity.rangeOwnProps), freezing it against a
+// concurrent Delete for the duration of its own row. Discovery itself
+// is not atomic across keys, so an Entity Put with a newly-codec'd key
+// after it was discovered may or may not have that Prop included; an
+// Entity fully Deleted before its row is serialized is omitted rather
+// than included with a partial Prop set.
+func (u *Universe) Snapshot() (*Snapshot, error) {
+	order := []uint64(nil)
+	entities := map[uint64]*Entity{}
+
+	for _, key := range u.propDB.keys() {
+		if _, ok := lookupPropCodec(key); !ok {
+			continue
+		}
+		u.Range(key, func(p *Prop) bool {
+			e := p.Entity()
+			if e == nil {
+				return true
+			}
+			if _, ok := entities[e.id]; !ok {
+				entities[e.id] = e
+				order = append(order, e.id)
+			}
+			return true
+		})
+	}
+
+	snap := &Snapshot{Entities: make([]EntitySnapshot, 0, len(order))}
+	for _, id := range order {
+		es := EntitySnapshot{ID: id}
+		var encErr error
+		entities[id].rangeOwnProps(func(key string, p *Prop) {
+			if encErr != nil {
+				return
+			}
+			codec, ok := lookupPropCodec(key)
+			if !ok {
+				return
+			}
+			data, err := codec.EncodeProp(p.Data())
+			if err != nil {
+				encErr = err
+				return
+			}
+			es.Props = append(es.Props, PropSnapshot{Key: key, Data: data})
+		})
+		if encErr != nil {
+			return nil, encErr
+		}
+		if len(es.Props) == 0 {
+			continue
+		}
+		slices.SortFunc(es.Props, func(a, b PropSnapshot) int { return strings.Compare(a.Key, b.Key) })
+		snap.Entities = append(snap.Entities, es)
+	}
+	return snap, nil
+}
+
+// Restore adds snap's Entities and Props to u, decoding each Prop's Data
+// with the PropCodec registered for its key (Props under keys with no
+// registered codec are skipped). Restore does not clear u first; to get
+// an exact replica of the Universe a Snapshot was taken from, Restore
+// into a fresh Universe.
+func (u *Universe) Restore(snap *Snapshot) error {
+	for _, es := range snap.Entities {
+		e := u.Entity()
+		e.id = es.ID
+		u.advanceNextIDPast(es.ID)
+		for _, ps := range es.Props {
+			codec, ok := lookupPropCodec(ps.Key)
+			if !ok {
+				continue
+			}
+			data, err := codec.DecodeProp(ps.Data)
+			if err != nil {
+				return err
+			}
+			e.Put(ps.Key, data)
+		}
+	}
+	return nil
+}
+
+// EncodeJSON writes the Snapshot to w as JSON.
+func (s *Snapshot) EncodeJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+// EncodeCBOR writes the Snapshot to w as CBOR (RFC 8949): an array of
+// per-Entity maps, each with an "id" unsigned int and a "props" array of
+// {"key": text string, "data": byte string} maps. CBOR gives a more
+// compact binary encoding of game state than EncodeJSON.
+func (s *Snapshot) EncodeCBOR(w io.Writer) error {
+	if err := cborWriteArrayHead(w, len(s.Entities)); err != nil {
+		return err
+	}
+	for _, es := range s.Entities {
+		if err := cborWriteMapHead(w, 2); err != nil {
+			return err
+		}
+		if err := cborWriteText(w, "id"); err != nil {
+			return err
+		}
+		if err := cborWriteUint(w, es.ID); err != nil {
+			return err
+		}
+		if err := cborWriteText(w, "props"); err != nil {
+			return err
+		}
+		if err := cborWriteArrayHead(w, len(es.Props)); err != nil {
+			return err
+		}
+		for _, ps := range es.Props {
+			if err := cborWriteMapHead(w, 2); err != nil {
+				return err
+			}
+			if err := cborWriteText(w, "key"); err != nil {
+				return err
+			}
+			if err := cborWriteText(w, ps.Key); err != nil {
+				return err
+			}
+			if err := cborWriteText(w, "data"); err != nil {
+				return err
+			}
+			if err := cborWriteBytes(w, ps.Data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
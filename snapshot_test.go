@@ -0,0 +1,129 @@
+package ecs
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+// intCodec is a trivial PropCodec for "hp"-style int Props, used to
+// exercise Snapshot/Restore/EncodeJSON/EncodeCBOR.
+type intCodec struct{}
+
+func (intCodec) EncodeProp(data any) ([]byte, error) {
+	return []byte(strconv.Itoa(data.(int))), nil
+}
+
+func (intCodec) DecodeProp(raw []byte) (any, error) {
+	return strconv.Atoi(string(raw))
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	RegisterPropCodec("snaptest-hp", intCodec{})
+
+	var u Universe
+	e := u.Entity()
+	e.Put("snaptest-hp", 42)
+
+	snap, err := u.Snapshot()
+	if err != nil {
+		t.Fatalf("TestSnapshotRestore(): Snapshot() error = %v", err)
+	}
+	if got, want := len(snap.Entities), 1; got != want {
+		t.Fatalf("TestSnapshotRestore(): got %d Entities in snapshot, want %d", got, want)
+	}
+
+	var u2 Universe
+	if err := u2.Restore(snap); err != nil {
+		t.Fatalf("TestSnapshotRestore(): Restore() error = %v", err)
+	}
+
+	got := 0
+	u2.Range("snaptest-hp", func(p *Prop) bool {
+		got++
+		if want := 42; p.Data() != want {
+			t.Errorf("TestSnapshotRestore(): got Data() = %v, want %v", p.Data(), want)
+		}
+		return true
+	})
+	if want := 1; got != want {
+		t.Errorf("TestSnapshotRestore(): got %d restored Props, want %d", got, want)
+	}
+}
+
+func TestRestoreAdvancesNextID(t *testing.T) {
+	RegisterPropCodec("snaptest-nextid", intCodec{})
+
+	var u Universe
+	u.Entity() // ID 1, not included in the snapshot.
+	e2 := u.Entity()
+	e2.Put("snaptest-nextid", 1) // ID 2.
+
+	snap, err := u.Snapshot()
+	if err != nil {
+		t.Fatalf("TestRestoreAdvancesNextID(): Snapshot() error = %v", err)
+	}
+
+	var u2 Universe
+	if err := u2.Restore(snap); err != nil {
+		t.Fatalf("TestRestoreAdvancesNextID(): Restore() error = %v", err)
+	}
+
+	next := u2.Entity()
+	if next.ID() <= e2.ID() {
+		t.Errorf("TestRestoreAdvancesNextID(): got new Entity ID %d, want greater than restored ID %d", next.ID(), e2.ID())
+	}
+}
+
+func TestSnapshotSkipsEntityDeletedBeforeSerialization(t *testing.T) {
+	RegisterPropCodec("snaptest-frozen-a", intCodec{})
+	RegisterPropCodec("snaptest-frozen-b", intCodec{})
+
+	var u Universe
+	e := u.Entity()
+	e.Put("snaptest-frozen-a", 1)
+	e.Put("snaptest-frozen-b", 2)
+
+	// Delete e before taking the Snapshot; its Props are detached, so it
+	// must not appear in the Snapshot at all.
+	e.Delete()
+
+	snap, err := u.Snapshot()
+	if err != nil {
+		t.Fatalf("TestSnapshotSkipsEntityDeletedBeforeSerialization(): Snapshot() error = %v", err)
+	}
+	for _, es := range snap.Entities {
+		if es.ID == e.ID() {
+			t.Errorf("TestSnapshotSkipsEntityDeletedBeforeSerialization(): got Deleted Entity %d in snapshot, want omitted", e.ID())
+		}
+	}
+}
+
+func TestSnapshotEncodeJSONAndCBOR(t *testing.T) {
+	RegisterPropCodec("snaptest-mana", intCodec{})
+
+	var u Universe
+	e := u.Entity()
+	e.Put("snaptest-mana", 7)
+
+	snap, err := u.Snapshot()
+	if err != nil {
+		t.Fatalf("TestSnapshotEncodeJSONAndCBOR(): Snapshot() error = %v", err)
+	}
+
+	var jsonBuf bytes.Buffer
+	if err := snap.EncodeJSON(&jsonBuf); err != nil {
+		t.Fatalf("TestSnapshotEncodeJSONAndCBOR(): EncodeJSON() error = %v", err)
+	}
+	if jsonBuf.Len() == 0 {
+		t.Errorf("TestSnapshotEncodeJSONAndCBOR(): EncodeJSON() wrote no bytes")
+	}
+
+	var cborBuf bytes.Buffer
+	if err := snap.EncodeCBOR(&cborBuf); err != nil {
+		t.Fatalf("TestSnapshotEncodeJSONAndCBOR(): EncodeCBOR() error = %v", err)
+	}
+	if cborBuf.Len() == 0 {
+		t.Errorf("TestSnapshotEncodeJSONAndCBOR(): EncodeCBOR() wrote no bytes")
+	}
+}
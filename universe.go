@@ -1,5 +1,7 @@
 package ecs
 
+import "sync/atomic"
+
 // Universe provides an isolated scope for Entities and their Props.
 //
 // An Entity must be assoaicted with a Universe to access the Range method.
@@ -7,7 +9,60 @@ package ecs
 // Universe is safe for concurrent use.
 type Universe struct {
 	propDB
+	cmds   Commands
+	tick   atomic.Uint64
+	nextID atomic.Uint64
+	log    atomic.Pointer[CommandLog]
+}
+
+// Entity creates a new Entity in this Universe, assigning it a stable ID
+// unique within the Universe (see Entity.ID).
+func (u *Universe) Entity() *Entity {
+	return &Entity{u: u, id: u.nextID.Add(1)}
+}
+
+// advanceNextIDPast ensures the next Universe.Entity call assigns an ID
+// greater than id. Restore and Replay call this after assigning an
+// Entity an explicit recorded ID, so IDs generated afterwards can never
+// collide with one a Snapshot or CommandLog already vouches for.
+func (u *Universe) advanceNextIDPast(id uint64) {
+	for {
+		cur := u.nextID.Load()
+		if cur >= id {
+			return
+		}
+		if u.nextID.CompareAndSwap(cur, id) {
+			return
+		}
+	}
 }
 
-// Entity creates a new Entity in this Universe.
-func (u *Universe) Entity() *Entity { return &Entity{u: u} }
+// SetCommandLog attaches log to the Universe so subsequent Put, Remove,
+// and Delete calls on its Entities are recorded to it (see CommandLog).
+// Passing nil detaches any previously attached log.
+func (u *Universe) SetCommandLog(log *CommandLog) { u.log.Store(log) }
+
+// CommandLog returns the Universe's currently attached CommandLog, or nil
+// if none has been set with SetCommandLog.
+func (u *Universe) CommandLog() *CommandLog { return u.log.Load() }
+
+// Tick advances the Universe's generation counter by one and returns the
+// new tick. Systems call Tick once per frame and pass the previous tick
+// to RangeAdded / RangeChanged to process only what changed since then.
+func (u *Universe) Tick() uint64 { return u.tick.Add(1) }
+
+// Commands returns the Universe's Commands buffer for deferring
+// structural mutations to a well-defined sync point. See Commands and
+// RangeCommands.
+func (u *Universe) Commands() *Commands { return &u.cmds }
+
+// RangeCommands works like Range but also passes fn the Universe's
+// Commands buffer, flushing it against u once the Range completes. Use
+// this instead of Range when fn needs to Put, Remove, Delete, or Spawn
+// Entities in response to what it sees, rather than mutating the
+// Universe directly mid-iteration.
+func (u *Universe) RangeCommands(key string, fn func(*Prop, *Commands) bool) {
+	cmds := u.Commands()
+	u.Range(key, func(p *Prop) bool { return fn(p, cmds) })
+	cmds.Flush(u)
+}